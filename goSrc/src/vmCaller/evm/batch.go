@@ -0,0 +1,447 @@
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/native"
+	pb "iroha_protocol"
+)
+
+// batchTTL bounds how long a beginBatch session can sit open without a
+// matching commitBatch/abortBatch, so a caller that opens a batch and
+// disappears doesn't leak it (and its staged commands) forever.
+const batchTTL = 10 * time.Minute
+
+// batchSession accumulates the Iroha commands a single EVM caller has
+// staged between beginBatch and commitBatch/abortBatch, so they can be
+// submitted as one atomic Iroha transaction via iroha.SubmitBatch instead
+// of one Iroha transaction per snative call.
+type batchSession struct {
+	mu       sync.Mutex
+	commands []stagedCommand
+	opened   time.Time
+}
+
+// stagedCommand pairs the Iroha command staged for one snative call with
+// the EVM log it would have emitted immediately outside of a batch, so
+// commitBatch/executeBatch can publish the same events the direct
+// handlers do, but only once the batch's commands are actually committed.
+type stagedCommand struct {
+	command *pb.Command
+	event   eventSpec
+}
+
+type eventSpec struct {
+	signature string
+	indexed   []string
+	data      []string
+}
+
+var (
+	batchesMu        sync.Mutex
+	batches          = map[crypto.Address]*batchSession{}
+	batchEvictorOnce sync.Once
+)
+
+// startBatchEvictor launches, at most once per process, the background
+// loop that drops batch sessions left open past batchTTL.
+func startBatchEvictor() {
+	batchEvictorOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(batchTTL)
+			defer ticker.Stop()
+			for range ticker.C {
+				evictStaleBatches()
+			}
+		}()
+	})
+}
+
+// evictStaleBatches drops any open batch session older than batchTTL.
+func evictStaleBatches() {
+	batchesMu.Lock()
+	defer batchesMu.Unlock()
+	for caller, session := range batches {
+		if time.Since(session.opened) > batchTTL {
+			delete(batches, caller)
+		}
+	}
+}
+
+// tryStage appends the Iroha command and event for (op, args) to the
+// caller's open batch, if it has one. It reports whether the call was
+// staged; callers should execute the op immediately when it returns false
+// so that non-batched usage is unaffected.
+func tryStage(ctx native.Context, op string, args interface{}) (bool, error) {
+	batchesMu.Lock()
+	session, active := batches[ctx.CallFrame.Caller.Address]
+	batchesMu.Unlock()
+	if !active {
+		return false, nil
+	}
+
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return true, err
+	}
+	staged, err := stagedCommandForOp(op, raw)
+	if err != nil {
+		return true, err
+	}
+
+	session.mu.Lock()
+	session.commands = append(session.commands, staged)
+	session.mu.Unlock()
+
+	return true, nil
+}
+
+// commandForOp builds the Iroha command protobuf for one ServiceContract
+// op, reusing that function's own args struct so the JSON shape accepted
+// here matches what the ABI layer already decodes for it.
+func commandForOp(op string, raw json.RawMessage) (*pb.Command, error) {
+	staged, err := stagedCommandForOp(op, raw)
+	if err != nil {
+		return nil, err
+	}
+	return staged.command, nil
+}
+
+// stagedCommandForOp builds both the Iroha command and the EVM event for
+// one ServiceContract op, so batched execution (tryStage, executeBatch)
+// publishes the same events as the direct, unbatched handlers.
+func stagedCommandForOp(op string, raw json.RawMessage) (stagedCommand, error) {
+	switch op {
+	case "transferAsset":
+		var args transferAssetArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_TransferAsset{TransferAsset: &pb.TransferAsset{
+				SrcAccountId:  args.Src,
+				DestAccountId: args.Dst,
+				AssetId:       args.Asset,
+				Description:   args.Desc,
+				Amount:        args.Amount,
+			}}},
+			event: eventSpec{"AssetTransferred(string,string,string,string,string)",
+				[]string{args.Src, args.Dst}, []string{args.Asset, args.Amount, args.Desc}},
+		}, nil
+	case "createAccount":
+		var args createAccountArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_CreateAccount{CreateAccount: &pb.CreateAccount{
+				AccountName: args.Name,
+				DomainId:    args.Domain,
+				PublicKey:   args.Key,
+			}}},
+			event: eventSpec{"AccountCreated(string,string,string)",
+				[]string{args.Name, args.Domain}, []string{args.Key}},
+		}, nil
+	case "addAsset":
+		var args addAssetArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_AddAssetQuantity{AddAssetQuantity: &pb.AddAssetQuantity{
+				AssetId: args.Asset,
+				Amount:  args.Amount,
+			}}},
+			event: eventSpec{"AssetAdded(string,string)", []string{args.Asset}, []string{args.Amount}},
+		}, nil
+	case "subtractAsset":
+		var args subtractAssetArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_SubtractAssetQuantity{SubtractAssetQuantity: &pb.SubtractAssetQuantity{
+				AssetId: args.Asset,
+				Amount:  args.Amount,
+			}}},
+			event: eventSpec{"AssetSubtracted(string,string)", []string{args.Asset}, []string{args.Amount}},
+		}, nil
+	case "setAccountDetail":
+		var args setAccountDetailArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_SetAccountDetail{SetAccountDetail: &pb.SetAccountDetail{
+				AccountId: args.Account,
+				Key:       args.Key,
+				Value:     args.Value,
+			}}},
+			event: eventSpec{"AccountDetailSet(string,string,string)",
+				[]string{args.Account}, []string{args.Key, args.Value}},
+		}, nil
+	case "setAccountQuorum":
+		var args setAccountQuorumArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_SetAccountQuorum{SetAccountQuorum: &pb.SetAccountQuorum{
+				AccountId: args.Account,
+				Quorum:    args.Quorum,
+			}}},
+			event: eventSpec{"AccountQuorumSet(string,string)", []string{args.Account}, []string{args.Quorum}},
+		}, nil
+	case "addSignatory":
+		var args addSignatoryArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_AddSignatory{AddSignatory: &pb.AddSignatory{
+				AccountId: args.Account,
+				PublicKey: args.Key,
+			}}},
+			event: eventSpec{"SignatoryAdded(string,string)", []string{args.Account}, []string{args.Key}},
+		}, nil
+	case "removeSignatory":
+		var args removeSignatoryArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_RemoveSignatory{RemoveSignatory: &pb.RemoveSignatory{
+				AccountId: args.Account,
+				PublicKey: args.Key,
+			}}},
+			event: eventSpec{"SignatoryRemoved(string,string)", []string{args.Account}, []string{args.Key}},
+		}, nil
+	case "createDomain":
+		var args createDomainArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_CreateDomain{CreateDomain: &pb.CreateDomain{
+				DomainId:    args.Domain,
+				DefaultRole: args.Role,
+			}}},
+			event: eventSpec{"DomainCreated(string,string)", []string{args.Domain}, []string{args.Role}},
+		}, nil
+	case "createAsset":
+		var args createAssetArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_CreateAsset{CreateAsset: &pb.CreateAsset{
+				AssetName: args.Name,
+				DomainId:  args.Domain,
+				Precision: args.Precision,
+			}}},
+			event: eventSpec{"AssetCreated(string,string,string)",
+				[]string{args.Name, args.Domain}, []string{args.Precision}},
+		}, nil
+	case "appendRole":
+		var args appendRoleArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_AppendRole{AppendRole: &pb.AppendRole{
+				AccountId: args.Account,
+				RoleName:  args.Role,
+			}}},
+			event: eventSpec{"RoleAppended(string,string)", []string{args.Account}, []string{args.Role}},
+		}, nil
+	case "detachRole":
+		var args detachRoleArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_DetachRole{DetachRole: &pb.DetachRole{
+				AccountId: args.Account,
+				RoleName:  args.Role,
+			}}},
+			event: eventSpec{"RoleDetached(string,string)", []string{args.Account}, []string{args.Role}},
+		}, nil
+	case "addPeer":
+		var args addPeerArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_AddPeer{AddPeer: &pb.AddPeer{
+				Peer: &pb.Peer{Address: args.Address, PeerKey: args.PeerKey},
+			}}},
+			event: eventSpec{"PeerAdded(string,string)", []string{args.Address}, []string{args.PeerKey}},
+		}, nil
+	case "removePeer":
+		var args removePeerArgs
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return stagedCommand{}, err
+		}
+		return stagedCommand{
+			command: &pb.Command{Command: &pb.Command_RemovePeer{RemovePeer: &pb.RemovePeer{
+				PublicKey: args.PeerKey,
+			}}},
+			event: eventSpec{"PeerRemoved(string)", []string{args.PeerKey}, nil},
+		}, nil
+	default:
+		return stagedCommand{}, fmt.Errorf("executeBatch: unsupported op %q", op)
+	}
+}
+
+// emitStagedEvents publishes the EVM log for every staged command, in
+// order, after their Iroha transaction has already committed successfully.
+// The underlying mutations can't be rolled back at this point, so a sink
+// failure on one event is traced and skipped rather than reported as a
+// failure of the (already-succeeded) commitBatch/executeBatch call.
+func emitStagedEvents(ctx native.Context, function string, staged []stagedCommand) {
+	for _, s := range staged {
+		if err := emitEvent(ctx, s.event.signature, s.event.indexed, s.event.data...); err != nil {
+			logEventFailure(ctx, function, err)
+		}
+	}
+}
+
+type beginBatchArgs struct {
+}
+
+type beginBatchRets struct {
+	Result bool
+}
+
+func beginBatch(ctx native.Context, args beginBatchArgs) (beginBatchRets, error) {
+	startBatchEvictor()
+
+	caller := ctx.CallFrame.Caller.Address
+
+	batchesMu.Lock()
+	defer batchesMu.Unlock()
+	if _, active := batches[caller]; active {
+		return beginBatchRets{Result: false}, fmt.Errorf("a batch is already open for caller %v", caller)
+	}
+	batches[caller] = &batchSession{opened: time.Now()}
+
+	return beginBatchRets{Result: true}, nil
+}
+
+type commitBatchArgs struct {
+}
+
+type commitBatchRets struct {
+	Result bool
+}
+
+func commitBatch(ctx native.Context, args commitBatchArgs) (commitBatchRets, error) {
+	caller := ctx.CallFrame.Caller.Address
+
+	batchesMu.Lock()
+	session, active := batches[caller]
+	delete(batches, caller)
+	batchesMu.Unlock()
+	if !active {
+		return commitBatchRets{Result: false}, fmt.Errorf("no open batch for caller %v", caller)
+	}
+
+	commands := make([]*pb.Command, 0, len(session.commands))
+	for _, staged := range session.commands {
+		commands = append(commands, staged.command)
+	}
+
+	if err := iroha.SubmitBatch(commands); err != nil {
+		return commitBatchRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "commitBatch", "commands", len(session.commands))
+
+	emitStagedEvents(ctx, "commitBatch", session.commands)
+
+	return commitBatchRets{Result: true}, nil
+}
+
+type abortBatchArgs struct {
+}
+
+type abortBatchRets struct {
+	Result bool
+}
+
+func abortBatch(ctx native.Context, args abortBatchArgs) (abortBatchRets, error) {
+	caller := ctx.CallFrame.Caller.Address
+
+	batchesMu.Lock()
+	_, active := batches[caller]
+	delete(batches, caller)
+	batchesMu.Unlock()
+	if !active {
+		return abortBatchRets{Result: false}, fmt.Errorf("no open batch for caller %v", caller)
+	}
+
+	ctx.Logger.Trace.Log("function", "abortBatch")
+
+	return abortBatchRets{Result: true}, nil
+}
+
+type executeBatchArgs struct {
+	TxJSON string
+}
+
+type executeBatchRets struct {
+	Result bool
+}
+
+// executeBatch accepts a JSON-encoded list of {op, args} tuples, validates
+// each op against the existing ServiceContract handlers and submits the
+// whole sequence as a single Iroha transaction, so e.g. a CreateAccount +
+// AppendRole + AddAssetQuantity + TransferAsset onboarding flow commits or
+// reverts atomically instead of as four independent transactions.
+func executeBatch(ctx native.Context, args executeBatchArgs) (executeBatchRets, error) {
+	var ops []batchOp
+	if err := json.Unmarshal([]byte(args.TxJSON), &ops); err != nil {
+		return executeBatchRets{Result: false}, fmt.Errorf("executeBatch: invalid TxJSON: %w", err)
+	}
+
+	staged := make([]stagedCommand, 0, len(ops))
+	for _, op := range ops {
+		if err := checkPermission(ctx, op.Op); err != nil {
+			return executeBatchRets{Result: false}, err
+		}
+		cmd, err := stagedCommandForOp(op.Op, op.Args)
+		if err != nil {
+			return executeBatchRets{Result: false}, err
+		}
+		staged = append(staged, cmd)
+	}
+
+	commands := make([]*pb.Command, 0, len(staged))
+	for _, s := range staged {
+		commands = append(commands, s.command)
+	}
+
+	if err := iroha.SubmitBatch(commands); err != nil {
+		return executeBatchRets{Result: false}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "executeBatch", "commands", len(commands))
+
+	emitStagedEvents(ctx, "executeBatch", staged)
+
+	return executeBatchRets{Result: true}, nil
+}
+
+// batchOp is one entry of executeBatch's TxJSON: the ServiceContract
+// function name to invoke and its JSON-encoded args, decoded the same way
+// tryStage's op dispatch decodes staged calls.
+type batchOp struct {
+	Op   string          `json:"op"`
+	Args json.RawMessage `json:"args"`
+}
@@ -0,0 +1,117 @@
+package evm
+
+import (
+	stdbinary "encoding/binary"
+
+	"github.com/hyperledger/burrow/binary"
+	"github.com/hyperledger/burrow/crypto"
+	"github.com/hyperledger/burrow/execution/exec"
+	"github.com/hyperledger/burrow/execution/native"
+	"golang.org/x/crypto/sha3"
+)
+
+// eventSignature returns the keccak256 topic0 for a Solidity-style event
+// signature, e.g. "AssetTransferred(string,string,string,string,string)".
+func eventSignature(signature string) binary.Word256 {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(signature))
+	return binary.LeftPadWord256(hash.Sum(nil))
+}
+
+// stringTopic packs an indexed string parameter the way solc does: the
+// keccak256 hash of the UTF-8 bytes, left-padded to 32 bytes.
+func stringTopic(value string) binary.Word256 {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(value))
+	return binary.LeftPadWord256(hash.Sum(nil))
+}
+
+// abiEncodeStrings ABI-encodes a list of dynamic strings as a Solidity event
+// would lay them out in the non-indexed `data` portion of a log.
+func abiEncodeStrings(values ...string) []byte {
+	heads := make([]byte, 32*len(values))
+	var tails []byte
+	for i, v := range values {
+		offset := uint64(32*len(values) + len(tails))
+		stdbinary.BigEndian.PutUint64(heads[i*32+24:i*32+32], offset)
+		tails = append(tails, encodeDynamicString(v)...)
+	}
+	return append(heads, tails...)
+}
+
+func encodeDynamicString(value string) []byte {
+	length := len(value)
+	word := make([]byte, 32)
+	stdbinary.BigEndian.PutUint64(word[24:32], uint64(length))
+	padded := ((length + 31) / 32) * 32
+	data := make([]byte, padded)
+	copy(data, value)
+	return append(word, data...)
+}
+
+// emitEvent publishes an EVM log for a native Iroha side effect through the
+// same event sink regular contract LOG opcodes use, so both on-chain
+// contracts (via `abi.decode`) and JSON-RPC `eth_getLogs`/`eth_subscribe`
+// consumers observe it like any other Solidity event. This mirrors burrow's
+// snative event pattern, where native contract calls publish onto the
+// shared EVM event bus rather than a side channel.
+func emitEvent(ctx native.Context, signature string, indexed []string, data ...string) error {
+	topics := []binary.Word256{eventSignature(signature)}
+	for _, field := range indexed {
+		topics = append(topics, stringTopic(field))
+	}
+	return ctx.CallFrame.EventSink.Call(&exec.LogEvent{
+		Address: ServiceContractAddress,
+		Topics:  topics,
+		Data:    abiEncodeStrings(data...),
+	})
+}
+
+// logEventFailure records that the EVM log for an already-committed Iroha
+// mutation could not be published. The mutation itself succeeded and
+// can't be rolled back by failing to log it, so callers trace the error
+// here instead of reporting the call itself as failed.
+func logEventFailure(ctx native.Context, function string, err error) {
+	ctx.Logger.Trace.Log("function", function, "emitEvent error", err)
+}
+
+// ServiceContractAddress is the EVM address of the Iroha native service
+// contract, the same address IsNative recognizes.
+var ServiceContractAddress = mustAddressFromHex("a6abc17819738299b3b2c1ce46d55c74f04e290c")
+
+func mustAddressFromHex(hexAddr string) crypto.Address {
+	address, err := crypto.AddressFromHexString(hexAddr)
+	if err != nil {
+		panic(err)
+	}
+	return address
+}
+
+// IrohaEventsInterface is the Solidity companion to the generated
+// ServiceContract interface: it declares the events emitted by each
+// mutating native function so consumers can `abi.decode` log data without
+// depending on this package's Go types.
+const IrohaEventsInterface = `
+interface IrohaEvents {
+    event AccountCreated(string indexed name, string indexed domain, string key);
+    event AssetTransferred(string indexed src, string indexed dst, string asset, string amount, string desc);
+    event AssetAdded(string indexed asset, string amount);
+    event AssetSubtracted(string indexed asset, string amount);
+    event AccountDetailSet(string indexed account, string key, string value);
+    event AccountQuorumSet(string indexed account, string quorum);
+    event SignatoryAdded(string indexed account, string key);
+    event SignatoryRemoved(string indexed account, string key);
+    event DomainCreated(string indexed domain, string role);
+    event AssetCreated(string indexed name, string indexed domain, string precision);
+    event RoleAppended(string indexed account, string role);
+    event RoleDetached(string indexed account, string role);
+    event PeerAdded(string indexed address, string peerKey);
+    event PeerRemoved(string indexed peerKey);
+    event TransactionSigned(string indexed hash, string signer);
+    event TransactionCommitted(string indexed hash);
+    // Only emitted as a side effect of a subscribeBlocks/pollBlocks/unsubscribe
+    // call flushing the watcher's queue - poll pollBlocks for timely delivery,
+    // eth_subscribe("logs") alone will not see one from an idle subscription.
+    event NewBlock(string height, string hash, string txCount);
+}
+`
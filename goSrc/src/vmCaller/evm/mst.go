@@ -0,0 +1,119 @@
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/execution/native"
+	pb "iroha_protocol"
+)
+
+type proposeTransactionArgs struct {
+	Payload string
+}
+
+type proposeTransactionRets struct {
+	Hash string
+}
+
+// proposeTransaction builds an unsigned Iroha transaction out of a
+// JSON-encoded list of {op, args} tuples (the same shape executeBatch
+// accepts) and holds it in the MST pending pool, so a quorum-N Iroha
+// account can be driven from the EVM side instead of only ever submitting
+// single-signature transactions.
+func proposeTransaction(ctx native.Context, args proposeTransactionArgs) (proposeTransactionRets, error) {
+	var ops []batchOp
+	if err := json.Unmarshal([]byte(args.Payload), &ops); err != nil {
+		return proposeTransactionRets{}, err
+	}
+
+	commands := make([]*pb.Command, 0, len(ops))
+	for _, op := range ops {
+		if err := checkPermission(ctx, op.Op); err != nil {
+			return proposeTransactionRets{}, err
+		}
+		cmd, err := commandForOp(op.Op, op.Args)
+		if err != nil {
+			return proposeTransactionRets{}, err
+		}
+		commands = append(commands, cmd)
+	}
+
+	creator, err := iroha.GetAccount(serviceAccount)
+	if err != nil {
+		return proposeTransactionRets{}, fmt.Errorf("proposeTransaction: could not resolve quorum for %q: %w", serviceAccount, err)
+	}
+	quorum := int(creator.GetQuorum())
+
+	hash, err := iroha.ProposeTransaction(serviceAccount, quorum, commands)
+	if err != nil {
+		return proposeTransactionRets{}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "proposeTransaction", "hash", hash, "commands", len(commands))
+
+	return proposeTransactionRets{Hash: hash}, nil
+}
+
+type signPendingTransactionArgs struct {
+	Hash      string
+	Signature string
+}
+
+type signPendingTransactionRets struct {
+	Result bool
+}
+
+// signPendingTransaction accepts a hex-encoded 64-byte ed25519 signature
+// over a pending transaction, verifies it against the transaction
+// creator's signatories, and forwards the transaction to torii once
+// quorum is reached.
+func signPendingTransaction(ctx native.Context, args signPendingTransactionArgs) (signPendingTransactionRets, error) {
+	signer, committed, err := iroha.SignPendingTransaction(args.Hash, args.Signature)
+	if err != nil {
+		return signPendingTransactionRets{Result: false}, err
+	}
+
+	if err := emitEvent(ctx, "TransactionSigned(string,string)", []string{args.Hash}, signer); err != nil {
+		logEventFailure(ctx, "signPendingTransaction", err)
+	}
+
+	if committed {
+		if err := emitEvent(ctx, "TransactionCommitted(string)", []string{args.Hash}); err != nil {
+			logEventFailure(ctx, "signPendingTransaction", err)
+		}
+	}
+
+	ctx.Logger.Trace.Log("function", "signPendingTransaction",
+		"hash", args.Hash,
+		"signer", signer,
+		"committed", committed)
+
+	return signPendingTransactionRets{Result: true}, nil
+}
+
+type getPendingTransactionsArgs struct {
+	Account string
+}
+
+type getPendingTransactionsRets struct {
+	Result string
+}
+
+func getPendingTransactions(ctx native.Context, args getPendingTransactionsArgs) (getPendingTransactionsRets, error) {
+	txs, err := iroha.GetPendingTransactions(args.Account)
+	if err != nil {
+		return getPendingTransactionsRets{}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "getPendingTransactions", "account", args.Account, "count", len(txs))
+
+	result, err := json.Marshal(txs)
+	if err != nil {
+		return getPendingTransactionsRets{}, err
+	}
+
+	return getPendingTransactionsRets{Result: string(result)}, nil
+}
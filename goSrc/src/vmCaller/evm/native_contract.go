@@ -15,6 +15,8 @@ var (
 	ServiceContract = native.New().MustContract("ServiceContract",
 		`* acmstate.ReaderWriter for bridging EVM state and Iroha state.
 			* @dev This interface describes the functions exposed by the native service contracts layer in burrow.
+			* @dev Mutating functions also publish an event declared in IrohaEventsInterface (see events.go) so
+			* EVM contracts and eth_getLogs/eth_subscribe consumers can observe Iroha state changes.
 			`,
 		native.Function{
 			Comment: `
@@ -241,6 +243,152 @@ var (
 			PermFlag: permission.Call,
 			F:        getRolePermissions,
 		},
+		native.Function{
+			Comment: `
+				* @notice Opens a batch: individual snative calls made by this caller until commitBatch
+				* or abortBatch accumulate instead of being submitted to Iroha immediately
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        beginBatch,
+		},
+		native.Function{
+			Comment: `
+				* @notice Submits every command staged since beginBatch as a single atomic Iroha transaction
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        commitBatch,
+		},
+		native.Function{
+			Comment: `
+				* @notice Discards every command staged since beginBatch without submitting them
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        abortBatch,
+		},
+		native.Function{
+			Comment: `
+				* @notice Submits a JSON-encoded list of {op, args} tuples as a single atomic Iroha transaction
+				* @param TxJSON JSON array of {"op": "<function name>", "args": {...}} tuples
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        executeBatch,
+		},
+		native.Function{
+			Comment: `
+				* @notice Gets a page of account detail records, optionally filtered by writer/key
+				* @param Account account id to be used
+				* @param Writer only return details written by this account, or "" for any writer
+				* @param Key only return details under this key, or "" for any key
+				* @param PageSize maximum number of records to return
+				* @param FirstRecordId cursor returned as NextCursor by a previous call, or "" to start from the beginning
+				* @return a page of matching detail records and the cursor for the next page
+				`,
+			PermFlag: permission.Call,
+			F:        getAccountDetailPaged,
+		},
+		native.Function{
+			Comment: `
+				* @notice Gets a page of an account's assets
+				* @param Account account id to be used
+				* @param PageSize maximum number of assets to return
+				* @param FirstAssetId asset id to start the page at, or "" to start from the beginning
+				* @return a page of matching assets and the cursor for the next page
+				`,
+			PermFlag: permission.Call,
+			F:        getAccountAssetsPaged,
+		},
+		native.Function{
+			Comment: `
+				* @notice Gets a page of an account's transactions
+				* @param Account account id to be used
+				* @param PageSize maximum number of transactions to return
+				* @param FirstTxHash transaction hash to start the page at, or "" to start from the beginning
+				* @return a page of matching transactions and the cursor for the next page
+				`,
+			PermFlag: permission.Call,
+			F:        getAccountTransactions,
+		},
+		native.Function{
+			Comment: `
+				* @notice Gets a page of blocks in a height range
+				* @param FromHeight height to start the range at
+				* @param ToHeight height to end the range at (inclusive)
+				* @param PageSize maximum number of blocks to return
+				* @return a page of blocks and the height to resume from, empty once ToHeight is reached
+				`,
+			PermFlag: permission.Call,
+			F:        getBlockRange,
+		},
+		native.Function{
+			Comment: `
+				* @notice Builds an unsigned Iroha transaction and holds it pending additional signatures
+				* @param Payload JSON array of {"op": "<function name>", "args": {...}} tuples, as executeBatch accepts
+				* @return hash of the pending transaction
+				`,
+			PermFlag: permission.Call,
+			F:        proposeTransaction,
+		},
+		native.Function{
+			Comment: `
+				* @notice Adds a signature to a pending transaction, submitting it once quorum is reached
+				* @param Hash hash of the pending transaction, as returned by proposeTransaction
+				* @param Signature hex-encoded 64-byte ed25519 signature over the transaction, from one of the creator's signatories
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        signPendingTransaction,
+		},
+		native.Function{
+			Comment: `
+				* @notice Gets the transactions proposed for an account that are still awaiting signatures
+				* @param Account account id to be used
+				* @return pending transactions for the account
+				`,
+			PermFlag: permission.Call,
+			F:        getPendingTransactions,
+		},
+		native.Function{
+			Comment: `
+				* @notice Opens a subscription that buffers blocks committed from FromHeight onward
+				* @notice A NewBlock log is only emitted as a side effect of calling subscribeBlocks/pollBlocks/unsubscribe - eth_subscribe("logs") alone will not receive one from an idle subscription, call pollBlocks periodically instead
+				* @param FromHeight height to start the subscription at
+				* @return id of the subscription, to be passed to pollBlocks/unsubscribe
+				`,
+			PermFlag: permission.Call,
+			F:        subscribeBlocks,
+		},
+		native.Function{
+			Comment: `
+				* @notice Drains up to MaxItems buffered blocks from a subscription opened by subscribeBlocks, and emits the NewBlock log for any blocks committed since the last flush
+				* @param SubscriptionId id returned by subscribeBlocks
+				* @param MaxItems maximum number of blocks to return
+				* @return the drained blocks, oldest first
+				`,
+			PermFlag: permission.Call,
+			F:        pollBlocks,
+		},
+		native.Function{
+			Comment: `
+				* @notice Closes a subscription opened by subscribeBlocks
+				* @param SubscriptionId id returned by subscribeBlocks
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        unsubscribe,
+		},
+		native.Function{
+			Comment: `
+				* @notice Declares which Iroha account the caller acts as for checkPermission's purposes
+				* @param IrohaAccount Iroha account id the caller is registering itself as
+				* @return 'true' if successful, 'false' otherwise
+				`,
+			PermFlag: permission.Call,
+			F:        registerAccount,
+		},
 	)
 )
 
@@ -288,6 +436,10 @@ type transferAssetRets struct {
 }
 
 func transferAsset(ctx native.Context, args transferAssetArgs) (transferAssetRets, error) {
+	if staged, err := tryStage(ctx, "transferAsset", args); staged {
+		return transferAssetRets{Result: err == nil}, err
+	}
+
 	err := iroha.TransferAsset(args.Src, args.Dst, args.Asset, args.Desc, args.Amount)
 	if err != nil {
 		return transferAssetRets{Result: false}, err
@@ -300,6 +452,11 @@ func transferAsset(ctx native.Context, args transferAssetArgs) (transferAssetRet
 		"description", args.Desc,
 		"amount", args.Amount)
 
+	if err := emitEvent(ctx, "AssetTransferred(string,string,string,string,string)",
+		[]string{args.Src, args.Dst}, args.Asset, args.Amount, args.Desc); err != nil {
+		logEventFailure(ctx, "transferAsset", err)
+	}
+
 	return transferAssetRets{Result: true}, nil
 }
 
@@ -314,6 +471,14 @@ type createAccountRets struct {
 }
 
 func createAccount(ctx native.Context, args createAccountArgs) (createAccountRets, error) {
+	if err := checkPermission(ctx, "createAccount"); err != nil {
+		return createAccountRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "createAccount", args); staged {
+		return createAccountRets{Result: err == nil}, err
+	}
+
 	err := iroha.CreateAccount(args.Name, args.Domain, args.Key)
 	if err != nil {
 		return createAccountRets{Result: false}, err
@@ -324,6 +489,11 @@ func createAccount(ctx native.Context, args createAccountArgs) (createAccountRet
 		"domain", args.Domain,
 		"key", args.Key)
 
+	if err := emitEvent(ctx, "AccountCreated(string,string,string)",
+		[]string{args.Name, args.Domain}, args.Key); err != nil {
+		logEventFailure(ctx, "createAccount", err)
+	}
+
 	return createAccountRets{Result: true}, nil
 }
 
@@ -337,6 +507,10 @@ type addAssetRets struct {
 }
 
 func addAsset(ctx native.Context, args addAssetArgs) (addAssetRets, error) {
+	if staged, err := tryStage(ctx, "addAsset", args); staged {
+		return addAssetRets{Result: err == nil}, err
+	}
+
 	err := iroha.AddAssetQuantity(args.Asset, args.Amount)
 	if err != nil {
 		return addAssetRets{Result: false}, err
@@ -346,6 +520,10 @@ func addAsset(ctx native.Context, args addAssetArgs) (addAssetRets, error) {
 		"asset", args.Asset,
 		"amount", args.Amount)
 
+	if err := emitEvent(ctx, "AssetAdded(string,string)", []string{args.Asset}, args.Amount); err != nil {
+		logEventFailure(ctx, "addAsset", err)
+	}
+
 	return addAssetRets{Result: true}, nil
 }
 
@@ -359,6 +537,10 @@ type subtractAssetRets struct {
 }
 
 func subtractAsset(ctx native.Context, args subtractAssetArgs) (subtractAssetRets, error) {
+	if staged, err := tryStage(ctx, "subtractAsset", args); staged {
+		return subtractAssetRets{Result: err == nil}, err
+	}
+
 	err := iroha.SubtractAssetQuantity(args.Asset, args.Amount)
 	if err != nil {
 		return subtractAssetRets{Result: false}, err
@@ -368,6 +550,10 @@ func subtractAsset(ctx native.Context, args subtractAssetArgs) (subtractAssetRet
 		"asset", args.Asset,
 		"amount", args.Amount)
 
+	if err := emitEvent(ctx, "AssetSubtracted(string,string)", []string{args.Asset}, args.Amount); err != nil {
+		logEventFailure(ctx, "subtractAsset", err)
+	}
+
 	return subtractAssetRets{Result: true}, nil
 }
 
@@ -382,6 +568,14 @@ type setAccountDetailRets struct {
 }
 
 func setAccountDetail(ctx native.Context, args setAccountDetailArgs) (setAccountDetailRets, error) {
+	if err := checkPermission(ctx, "setAccountDetail"); err != nil {
+		return setAccountDetailRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "setAccountDetail", args); staged {
+		return setAccountDetailRets{Result: err == nil}, err
+	}
+
 	err := iroha.SetAccountDetail(args.Account, args.Key, args.Value)
 	if err != nil {
 		return setAccountDetailRets{Result: false}, err
@@ -392,6 +586,11 @@ func setAccountDetail(ctx native.Context, args setAccountDetailArgs) (setAccount
 		"key", args.Key,
 		"value", args.Value)
 
+	if err := emitEvent(ctx, "AccountDetailSet(string,string,string)",
+		[]string{args.Account}, args.Key, args.Value); err != nil {
+		logEventFailure(ctx, "setAccountDetail", err)
+	}
+
 	return setAccountDetailRets{Result: true}, nil
 }
 
@@ -423,6 +622,14 @@ type setAccountQuorumRets struct {
 }
 
 func setAccountQuorum(ctx native.Context, args setAccountQuorumArgs) (setAccountQuorumRets, error) {
+	if err := checkPermission(ctx, "setAccountQuorum"); err != nil {
+		return setAccountQuorumRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "setAccountQuorum", args); staged {
+		return setAccountQuorumRets{Result: err == nil}, err
+	}
+
 	err := iroha.SetAccountQuorum(args.Account, args.Quorum)
 	if err != nil {
 		return setAccountQuorumRets{Result: false}, err
@@ -432,6 +639,10 @@ func setAccountQuorum(ctx native.Context, args setAccountQuorumArgs) (setAccount
 		"account", args.Account,
 		"quorum", args.Quorum)
 
+	if err := emitEvent(ctx, "AccountQuorumSet(string,string)", []string{args.Account}, args.Quorum); err != nil {
+		logEventFailure(ctx, "setAccountQuorum", err)
+	}
+
 	return setAccountQuorumRets{Result: true}, nil
 }
 
@@ -445,6 +656,14 @@ type addSignatoryRets struct {
 }
 
 func addSignatory(ctx native.Context, args addSignatoryArgs) (addSignatoryRets, error) {
+	if err := checkPermission(ctx, "addSignatory"); err != nil {
+		return addSignatoryRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "addSignatory", args); staged {
+		return addSignatoryRets{Result: err == nil}, err
+	}
+
 	err := iroha.AddSignatory(args.Account, args.Key)
 	if err != nil {
 		return addSignatoryRets{Result: false}, err
@@ -454,6 +673,10 @@ func addSignatory(ctx native.Context, args addSignatoryArgs) (addSignatoryRets,
 		"account id", args.Account,
 		"public key", args.Key)
 
+	if err := emitEvent(ctx, "SignatoryAdded(string,string)", []string{args.Account}, args.Key); err != nil {
+		logEventFailure(ctx, "addSignatory", err)
+	}
+
 	return addSignatoryRets{Result: true}, nil
 }
 
@@ -467,6 +690,14 @@ type removeSignatoryRets struct {
 }
 
 func removeSignatory(ctx native.Context, args removeSignatoryArgs) (removeSignatoryRets, error) {
+	if err := checkPermission(ctx, "removeSignatory"); err != nil {
+		return removeSignatoryRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "removeSignatory", args); staged {
+		return removeSignatoryRets{Result: err == nil}, err
+	}
+
 	err := iroha.RemoveSignatory(args.Account, args.Key)
 	if err != nil {
 		return removeSignatoryRets{Result: false}, err
@@ -476,6 +707,10 @@ func removeSignatory(ctx native.Context, args removeSignatoryArgs) (removeSignat
 		"account id", args.Account,
 		"public key", args.Key)
 
+	if err := emitEvent(ctx, "SignatoryRemoved(string,string)", []string{args.Account}, args.Key); err != nil {
+		logEventFailure(ctx, "removeSignatory", err)
+	}
+
 	return removeSignatoryRets{Result: true}, nil
 }
 
@@ -489,6 +724,14 @@ type createDomainRets struct {
 }
 
 func createDomain(ctx native.Context, args createDomainArgs) (createDomainRets, error) {
+	if err := checkPermission(ctx, "createDomain"); err != nil {
+		return createDomainRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "createDomain", args); staged {
+		return createDomainRets{Result: err == nil}, err
+	}
+
 	err := iroha.CreateDomain(args.Domain, args.Role)
 	if err != nil {
 		return createDomainRets{Result: false}, err
@@ -498,6 +741,10 @@ func createDomain(ctx native.Context, args createDomainArgs) (createDomainRets,
 		"domain name", args.Domain,
 		"default role", args.Role)
 
+	if err := emitEvent(ctx, "DomainCreated(string,string)", []string{args.Domain}, args.Role); err != nil {
+		logEventFailure(ctx, "createDomain", err)
+	}
+
 	return createDomainRets{Result: true}, nil
 }
 
@@ -533,6 +780,14 @@ type createAssetRets struct {
 }
 
 func createAsset(ctx native.Context, args createAssetArgs) (createAssetRets, error) {
+	if err := checkPermission(ctx, "createAsset"); err != nil {
+		return createAssetRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "createAsset", args); staged {
+		return createAssetRets{Result: err == nil}, err
+	}
+
 	err := iroha.CreateAsset(args.Name, args.Domain, args.Precision)
 	if err != nil {
 		return createAssetRets{Result: false}, err
@@ -543,6 +798,11 @@ func createAsset(ctx native.Context, args createAssetArgs) (createAssetRets, err
 		"domain id", args.Domain,
 		"precision", args.Precision)
 
+	if err := emitEvent(ctx, "AssetCreated(string,string,string)",
+		[]string{args.Name, args.Domain}, args.Precision); err != nil {
+		logEventFailure(ctx, "createAsset", err)
+	}
+
 	return createAssetRets{Result: true}, nil
 }
 
@@ -598,6 +858,14 @@ type appendRoleRets struct {
 }
 
 func appendRole(ctx native.Context, args appendRoleArgs) (appendRoleRets, error) {
+	if err := checkPermission(ctx, "appendRole"); err != nil {
+		return appendRoleRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "appendRole", args); staged {
+		return appendRoleRets{Result: err == nil}, err
+	}
+
 	err := iroha.AppendRole(args.Account, args.Role)
 	if err != nil {
 		return appendRoleRets{Result: false}, err
@@ -607,6 +875,10 @@ func appendRole(ctx native.Context, args appendRoleArgs) (appendRoleRets, error)
 		"account name", args.Account,
 		"new role", args.Role)
 
+	if err := emitEvent(ctx, "RoleAppended(string,string)", []string{args.Account}, args.Role); err != nil {
+		logEventFailure(ctx, "appendRole", err)
+	}
+
 	return appendRoleRets{Result: true}, nil
 }
 
@@ -620,6 +892,14 @@ type detachRoleRets struct {
 }
 
 func detachRole(ctx native.Context, args detachRoleArgs) (detachRoleRets, error) {
+	if err := checkPermission(ctx, "detachRole"); err != nil {
+		return detachRoleRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "detachRole", args); staged {
+		return detachRoleRets{Result: err == nil}, err
+	}
+
 	err := iroha.DetachRole(args.Account, args.Role)
 	if err != nil {
 		return detachRoleRets{Result: false}, err
@@ -629,6 +909,10 @@ func detachRole(ctx native.Context, args detachRoleArgs) (detachRoleRets, error)
 		"account name", args.Account,
 		"removed role", args.Role)
 
+	if err := emitEvent(ctx, "RoleDetached(string,string)", []string{args.Account}, args.Role); err != nil {
+		logEventFailure(ctx, "detachRole", err)
+	}
+
 	return detachRoleRets{Result: true}, nil
 }
 
@@ -642,6 +926,14 @@ type addPeerRets struct {
 }
 
 func addPeer(ctx native.Context, args addPeerArgs) (addPeerRets, error) {
+	if err := checkPermission(ctx, "addPeer"); err != nil {
+		return addPeerRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "addPeer", args); staged {
+		return addPeerRets{Result: err == nil}, err
+	}
+
 	err := iroha.AddPeer(args.Address, args.PeerKey)
 	if err != nil {
 		return addPeerRets{Result: false}, err
@@ -651,6 +943,10 @@ func addPeer(ctx native.Context, args addPeerArgs) (addPeerRets, error) {
 		"peer address", args.Address,
 		"peer key", args.PeerKey)
 
+	if err := emitEvent(ctx, "PeerAdded(string,string)", []string{args.Address}, args.PeerKey); err != nil {
+		logEventFailure(ctx, "addPeer", err)
+	}
+
 	return addPeerRets{Result: true}, nil
 }
 
@@ -663,6 +959,14 @@ type removePeerRets struct {
 }
 
 func removePeer(ctx native.Context, args removePeerArgs) (removePeerRets, error) {
+	if err := checkPermission(ctx, "removePeer"); err != nil {
+		return removePeerRets{Result: false}, err
+	}
+
+	if staged, err := tryStage(ctx, "removePeer", args); staged {
+		return removePeerRets{Result: err == nil}, err
+	}
+
 	err := iroha.RemovePeer(args.PeerKey)
 	if err != nil {
 		return removePeerRets{Result: false}, err
@@ -671,6 +975,10 @@ func removePeer(ctx native.Context, args removePeerArgs) (removePeerRets, error)
 	ctx.Logger.Trace.Log("function", "RemovePeer",
 		"peer key", args.PeerKey)
 
+	if err := emitEvent(ctx, "PeerRemoved(string)", []string{args.PeerKey}); err != nil {
+		logEventFailure(ctx, "removePeer", err)
+	}
+
 	return removePeerRets{Result: true}, nil
 }
 
@@ -745,19 +1053,26 @@ func getRolePermissions(ctx native.Context, args getRolePermissionsArgs) (getRol
 	return getRolePermissionsRets{Result: string(result)}, nil
 }
 
-func MustCreateNatives() *native.Natives {
-	ns, err := createNatives()
+func MustCreateNatives(opts ...func()) *native.Natives {
+	ns, err := createNatives(opts...)
 	if err != nil {
 		panic(err)
 	}
 	return ns
 }
 
-func createNatives() (*native.Natives, error) {
+func createNatives(opts ...func()) (*native.Natives, error) {
+	for _, opt := range opts {
+		opt()
+	}
+
 	ns, err := native.Merge(ServiceContract, native.Permissions, native.Precompiles)
 	if err != nil {
 		return nil, err
 	}
+
+	startBlockWatcher()
+
 	return ns, nil
 }
 
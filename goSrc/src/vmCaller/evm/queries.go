@@ -0,0 +1,111 @@
+package evm
+
+import (
+	"vmCaller/iroha"
+
+	"github.com/hyperledger/burrow/execution/native"
+)
+
+type getAccountDetailPagedArgs struct {
+	Account       string
+	Writer        string
+	Key           string
+	PageSize      string
+	FirstRecordId string
+}
+
+type getAccountDetailPagedRets struct {
+	Items      []string
+	NextCursor string
+}
+
+func getAccountDetailPaged(ctx native.Context, args getAccountDetailPagedArgs) (getAccountDetailPagedRets, error) {
+	page, err := iroha.GetAccountDetailPaged(args.Account, args.Writer, args.Key, args.PageSize, args.FirstRecordId)
+	if err != nil {
+		return getAccountDetailPagedRets{}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "getAccountDetailPaged",
+		"account", args.Account,
+		"writer", args.Writer,
+		"key", args.Key,
+		"pageSize", args.PageSize,
+		"firstRecordId", args.FirstRecordId)
+
+	return getAccountDetailPagedRets{Items: page.Items, NextCursor: page.NextCursor}, nil
+}
+
+type getAccountAssetsPagedArgs struct {
+	Account      string
+	PageSize     string
+	FirstAssetId string
+}
+
+type getAccountAssetsPagedRets struct {
+	Items      []string
+	NextCursor string
+}
+
+func getAccountAssetsPaged(ctx native.Context, args getAccountAssetsPagedArgs) (getAccountAssetsPagedRets, error) {
+	page, err := iroha.GetAccountAssetsPaged(args.Account, args.PageSize, args.FirstAssetId)
+	if err != nil {
+		return getAccountAssetsPagedRets{}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "getAccountAssetsPaged",
+		"account", args.Account,
+		"pageSize", args.PageSize,
+		"firstAssetId", args.FirstAssetId)
+
+	return getAccountAssetsPagedRets{Items: page.Items, NextCursor: page.NextCursor}, nil
+}
+
+type getAccountTransactionsArgs struct {
+	Account     string
+	PageSize    string
+	FirstTxHash string
+}
+
+type getAccountTransactionsRets struct {
+	Items      []string
+	NextCursor string
+}
+
+func getAccountTransactions(ctx native.Context, args getAccountTransactionsArgs) (getAccountTransactionsRets, error) {
+	page, err := iroha.GetAccountTransactions(args.Account, args.PageSize, args.FirstTxHash)
+	if err != nil {
+		return getAccountTransactionsRets{}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "getAccountTransactions",
+		"account", args.Account,
+		"pageSize", args.PageSize,
+		"firstTxHash", args.FirstTxHash)
+
+	return getAccountTransactionsRets{Items: page.Items, NextCursor: page.NextCursor}, nil
+}
+
+type getBlockRangeArgs struct {
+	FromHeight string
+	ToHeight   string
+	PageSize   string
+}
+
+type getBlockRangeRets struct {
+	Items      []string
+	NextCursor string
+}
+
+func getBlockRange(ctx native.Context, args getBlockRangeArgs) (getBlockRangeRets, error) {
+	page, err := iroha.GetBlockRange(args.FromHeight, args.ToHeight, args.PageSize)
+	if err != nil {
+		return getBlockRangeRets{}, err
+	}
+
+	ctx.Logger.Trace.Log("function", "getBlockRange",
+		"fromHeight", args.FromHeight,
+		"toHeight", args.ToHeight,
+		"pageSize", args.PageSize)
+
+	return getBlockRangeRets{Items: page.Items, NextCursor: page.NextCursor}, nil
+}
@@ -0,0 +1,339 @@
+package evm
+
+import (
+	"container/ring"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vmCaller/iroha"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/burrow/execution/native"
+	pb "iroha_protocol"
+)
+
+const (
+	subscriptionRingSize = 256
+	subscriptionTTL      = 10 * time.Minute
+
+	// notificationBufferSize bounds the global NewBlock notification queue
+	// so a process where nobody ever calls subscribeBlocks/pollBlocks (and
+	// so never flushes it) can't grow the queue without bound; once full,
+	// the oldest unflushed notifications are dropped in favor of newer ones.
+	notificationBufferSize = 1024
+)
+
+// subscription buffers committed blocks for one subscribeBlocks caller in
+// a fixed-size ring, so pollBlocks can drain them without unbounded
+// memory growth if the caller falls behind or stops polling.
+type subscription struct {
+	mu       sync.Mutex
+	buffer   *ring.Ring
+	buffered int
+	cancel   func()
+	lastPoll time.Time
+}
+
+var (
+	subsMu     sync.Mutex
+	subs       = map[string]*subscription{}
+	subCounter uint64
+)
+
+func nextSubscriptionID() string {
+	return strconv.FormatUint(atomic.AddUint64(&subCounter, 1), 10)
+}
+
+func (s *subscription) push(block string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buffer.Value = block
+	s.buffer = s.buffer.Next()
+	if s.buffered < subscriptionRingSize {
+		s.buffered++
+	}
+}
+
+func (s *subscription) drain(max int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if max < 0 || max > s.buffered {
+		max = s.buffered
+	}
+
+	// buffer currently points just past the most recently pushed slot, so
+	// walk back `buffered` entries to find the oldest undrained one.
+	cursor := s.buffer
+	for i := 0; i < s.buffered; i++ {
+		cursor = cursor.Prev()
+	}
+
+	items := make([]string, 0, max)
+	for i := 0; i < max; i++ {
+		items = append(items, cursor.Value.(string))
+		cursor = cursor.Next()
+		s.buffered--
+	}
+	s.lastPoll = time.Now()
+
+	return items
+}
+
+// evictStaleSubscriptions cancels and drops subscriptions that haven't
+// been polled within subscriptionTTL, so a DApp that stops polling
+// doesn't leak an open gRPC stream forever.
+func evictStaleSubscriptions() {
+	subsMu.Lock()
+	defer subsMu.Unlock()
+
+	for id, sub := range subs {
+		sub.mu.Lock()
+		stale := time.Since(sub.lastPoll) > subscriptionTTL
+		sub.mu.Unlock()
+		if stale {
+			sub.cancel()
+			delete(subs, id)
+		}
+	}
+}
+
+type subscribeBlocksArgs struct {
+	FromHeight string
+}
+
+type subscribeBlocksRets struct {
+	SubscriptionId string
+}
+
+func subscribeBlocks(ctx native.Context, args subscribeBlocksArgs) (subscribeBlocksRets, error) {
+	if err := flushBlockNotifications(ctx); err != nil {
+		return subscribeBlocksRets{}, err
+	}
+
+	height, err := strconv.ParseUint(args.FromHeight, 10, 64)
+	if err != nil {
+		return subscribeBlocksRets{}, err
+	}
+
+	stream, err := iroha.StreamBlocks(height)
+	if err != nil {
+		return subscribeBlocksRets{}, err
+	}
+
+	sub := &subscription{
+		buffer:   ring.New(subscriptionRingSize),
+		cancel:   stream.Cancel,
+		lastPoll: time.Now(),
+	}
+
+	id := nextSubscriptionID()
+
+	subsMu.Lock()
+	subs[id] = sub
+	subsMu.Unlock()
+
+	go pumpBlocks(sub, stream)
+
+	ctx.Logger.Trace.Log("function", "subscribeBlocks", "fromHeight", args.FromHeight, "subscriptionId", id)
+
+	return subscribeBlocksRets{SubscriptionId: id}, nil
+}
+
+// pumpBlocks forwards committed blocks from stream into sub's ring buffer
+// until the stream errors (typically because Cancel was called by
+// unsubscribe or TTL eviction).
+func pumpBlocks(sub *subscription, stream *iroha.BlockStream) {
+	for block := range stream.Blocks {
+		encoded, err := json.Marshal(block)
+		if err != nil {
+			continue
+		}
+		sub.push(string(encoded))
+	}
+}
+
+type pollBlocksArgs struct {
+	SubscriptionId string
+	MaxItems       string
+}
+
+type pollBlocksRets struct {
+	Items []string
+}
+
+func pollBlocks(ctx native.Context, args pollBlocksArgs) (pollBlocksRets, error) {
+	if err := flushBlockNotifications(ctx); err != nil {
+		return pollBlocksRets{}, err
+	}
+
+	maxItems, err := strconv.Atoi(args.MaxItems)
+	if err != nil {
+		return pollBlocksRets{}, err
+	}
+
+	subsMu.Lock()
+	sub, ok := subs[args.SubscriptionId]
+	subsMu.Unlock()
+	if !ok {
+		return pollBlocksRets{}, fmt.Errorf("pollBlocks: unknown subscription %q", args.SubscriptionId)
+	}
+
+	items := sub.drain(maxItems)
+
+	ctx.Logger.Trace.Log("function", "pollBlocks", "subscriptionId", args.SubscriptionId, "items", len(items))
+
+	return pollBlocksRets{Items: items}, nil
+}
+
+type unsubscribeArgs struct {
+	SubscriptionId string
+}
+
+type unsubscribeRets struct {
+	Result bool
+}
+
+func unsubscribe(ctx native.Context, args unsubscribeArgs) (unsubscribeRets, error) {
+	if err := flushBlockNotifications(ctx); err != nil {
+		return unsubscribeRets{}, err
+	}
+
+	subsMu.Lock()
+	sub, ok := subs[args.SubscriptionId]
+	delete(subs, args.SubscriptionId)
+	subsMu.Unlock()
+	if !ok {
+		return unsubscribeRets{Result: false}, fmt.Errorf("unsubscribe: unknown subscription %q", args.SubscriptionId)
+	}
+	sub.cancel()
+
+	ctx.Logger.Trace.Log("function", "unsubscribe", "subscriptionId", args.SubscriptionId)
+
+	return unsubscribeRets{Result: true}, nil
+}
+
+// newBlockNotification is a pending NewBlock log the background watcher
+// started by startBlockWatcher has observed but not yet published: burrow
+// only lets native code publish an EVM log through a call's
+// native.Context, so there is no event sink a goroutine outside of a
+// contract call can write to directly. Instead we queue notifications
+// here and the next subscribeBlocks/pollBlocks/unsubscribe call (which
+// does have a ctx) flushes them as real EVM logs.
+type newBlockNotification struct {
+	height  uint64
+	hash    string
+	txCount int
+}
+
+var (
+	notificationsMu sync.Mutex
+	notifications   []newBlockNotification
+
+	blockWatcherOnce sync.Once
+)
+
+// startBlockWatcher streams the chain from its current height and queues a
+// NewBlock notification for every newly committed block. It is started at
+// most once per process (MustCreateNatives may be called more than once in
+// a test binary, and a second stream would double-append notifications and
+// hold a second open connection). Starting from the current height, rather
+// than genesis, avoids re-queuing the chain's entire history on every
+// restart; operators who need older blocks already have getBlock/
+// getBlockRange for that.
+//
+// This watcher cannot itself publish the NewBlock log: burrow only exposes
+// an EVM event sink (ctx.CallFrame.EventSink) to code running inside a
+// contract call, and this goroutine runs outside of one. So on a node where
+// nothing ever calls subscribeBlocks/pollBlocks/unsubscribe, queued
+// notifications sit unflushed and no NewBlock log is ever emitted -
+// eth_subscribe("logs") alone is not a reliable way to observe new blocks.
+// A DApp that needs timely delivery should call pollBlocks periodically,
+// which both drains its own subscription and flushes the NewBlock logs as
+// a side effect.
+func startBlockWatcher() {
+	blockWatcherOnce.Do(func() {
+		height, err := iroha.CurrentHeight()
+		if err != nil {
+			return
+		}
+
+		stream, err := iroha.StreamBlocks(height)
+		if err != nil {
+			return
+		}
+
+		go func() {
+			ticker := time.NewTicker(subscriptionTTL)
+			defer ticker.Stop()
+			for {
+				select {
+				case block, ok := <-stream.Blocks:
+					if !ok {
+						<-stream.Errors
+						return
+					}
+					queueNotification(blockToNotification(block))
+				case <-ticker.C:
+					evictStaleSubscriptions()
+				}
+			}
+		}()
+	})
+}
+
+// queueNotification appends n to the pending notification queue, dropping
+// the oldest entry once notificationBufferSize is reached so an
+// unflushed queue can't grow without bound.
+func queueNotification(n newBlockNotification) {
+	notificationsMu.Lock()
+	defer notificationsMu.Unlock()
+	notifications = append(notifications, n)
+	if len(notifications) > notificationBufferSize {
+		notifications = notifications[len(notifications)-notificationBufferSize:]
+	}
+}
+
+func blockToNotification(block *pb.Block) newBlockNotification {
+	payload := block.GetBlockV1().GetPayload()
+	encoded, _ := proto.Marshal(payload)
+	sum := sha256.Sum256(encoded)
+	return newBlockNotification{
+		height:  payload.GetHeight(),
+		hash:    hex.EncodeToString(sum[:]),
+		txCount: len(payload.GetTransactions()),
+	}
+}
+
+// flushBlockNotifications publishes every notification queued since the
+// last flush as a NewBlock event. Notifications are only popped off the
+// queue once they've been published successfully, so an emitEvent failure
+// leaves the rest for the next flush instead of dropping them.
+//
+// This is the only place NewBlock logs are ever emitted, and it only runs
+// when subscribeBlocks/pollBlocks/unsubscribe is called with a ctx to
+// publish through - see startBlockWatcher for why the watcher can't flush
+// on its own.
+func flushBlockNotifications(ctx native.Context) error {
+	notificationsMu.Lock()
+	pending := notifications
+	notifications = nil
+	notificationsMu.Unlock()
+
+	for i, n := range pending {
+		if err := emitEvent(ctx, "NewBlock(string,string,string)",
+			nil, strconv.FormatUint(n.height, 10), n.hash, strconv.Itoa(n.txCount)); err != nil {
+			notificationsMu.Lock()
+			notifications = append(pending[i:], notifications...)
+			notificationsMu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package iroha
+
+import (
+	pb "iroha_protocol"
+)
+
+// SubmitBatch submits cmds as the command list of a single Iroha
+// transaction, reusing the same transaction-building/signing/torii-submit
+// plumbing every single-command helper in this package (TransferAsset,
+// CreateAccount, ...) already goes through, so a caller-supplied sequence
+// of commands commits or reverts atomically instead of one Iroha
+// transaction per command.
+func SubmitBatch(cmds []*pb.Command) error {
+	return sendCommands(cmds...)
+}
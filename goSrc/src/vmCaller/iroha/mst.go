@@ -0,0 +1,160 @@
+package iroha
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/sha3"
+
+	pb "iroha_protocol"
+)
+
+// PendingTransaction is an unsigned or partially-signed Iroha transaction
+// held in the pending pool until enough of the creator account's
+// signatories have signed it to satisfy its quorum.
+type PendingTransaction struct {
+	Hash       string
+	Tx         *pb.Transaction
+	Quorum     int
+	Signatures map[string]bool // hex-encoded signatory public key -> signed
+}
+
+var (
+	pendingMu sync.Mutex
+	pending   = map[string]*PendingTransaction{}
+)
+
+// ProposeTransaction builds an unsigned transaction for cmds, issued on
+// behalf of creator, and holds it in the pending pool keyed by its hash
+// until quorum additional signatures accumulate via SignPendingTransaction.
+func ProposeTransaction(creator string, quorum int, cmds []*pb.Command) (string, error) {
+	tx := &pb.Transaction{
+		Payload: &pb.Transaction_Payload{
+			ReducedPayload: &pb.Transaction_Payload_ReducedPayload{
+				Commands:         cmds,
+				CreatorAccountId: creator,
+				Quorum:           uint32(quorum),
+			},
+		},
+	}
+
+	hash, err := hashTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	pending[hash] = &PendingTransaction{
+		Hash:       hash,
+		Tx:         tx,
+		Quorum:     quorum,
+		Signatures: map[string]bool{},
+	}
+
+	return hash, nil
+}
+
+// SignPendingTransaction verifies signatureHex against every signatory of
+// the pending transaction's creator account (as reported by
+// GetSignatories) and, on a match, records that signatory's signature on
+// the transaction. It forwards the transaction to torii once enough
+// signatures have accumulated to meet its quorum, reporting whether this
+// call was the one that reached quorum and committed it.
+func SignPendingTransaction(hash, signatureHex string) (signer string, committed bool, err error) {
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return "", false, fmt.Errorf("signPendingTransaction: invalid signature encoding: %w", err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return "", false, fmt.Errorf("signPendingTransaction: expected a %d-byte ed25519 signature, got %d",
+			ed25519.SignatureSize, len(signature))
+	}
+
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+
+	tx, ok := pending[hash]
+	if !ok {
+		return "", false, fmt.Errorf("signPendingTransaction: no pending transaction with hash %q", hash)
+	}
+
+	message, err := reducedPayloadDigest(tx.Tx)
+	if err != nil {
+		return "", false, err
+	}
+
+	signatories, err := GetSignatories(tx.Tx.GetPayload().GetReducedPayload().GetCreatorAccountId())
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, candidate := range signatories {
+		pubKey, err := hex.DecodeString(candidate)
+		if err != nil || len(pubKey) != ed25519.PublicKeySize {
+			continue
+		}
+		if ed25519.Verify(pubKey, message, signature) {
+			tx.Signatures[candidate] = true
+			tx.Tx.Signatures = append(tx.Tx.Signatures, &pb.Signature{
+				PublicKey: candidate,
+				Signature: signatureHex,
+			})
+
+			if len(tx.Signatures) >= tx.Quorum {
+				if err := submitSignedTransaction(tx.Tx); err != nil {
+					return candidate, false, err
+				}
+				delete(pending, hash)
+				return candidate, true, nil
+			}
+
+			return candidate, false, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("signPendingTransaction: signature does not match any signatory of %q",
+		tx.Tx.GetPayload().GetReducedPayload().GetCreatorAccountId())
+}
+
+// GetPendingTransactions returns the transactions proposed for account
+// that are still awaiting signatures.
+func GetPendingTransactions(account string) ([]*PendingTransaction, error) {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+
+	var result []*PendingTransaction
+	for _, tx := range pending {
+		if tx.Tx.GetPayload().GetReducedPayload().GetCreatorAccountId() == account {
+			result = append(result, tx)
+		}
+	}
+	return result, nil
+}
+
+// hashTransaction computes a transaction's hash the way Iroha itself does:
+// SHA3-256 over the serialized reduced payload, not a raw-bytes SHA-256.
+// Signatories also sign this same digest, so a hash computed any other way
+// would neither match torii's own tx hash nor verify real signatures.
+func hashTransaction(tx *pb.Transaction) (string, error) {
+	digest, err := reducedPayloadDigest(tx)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+// reducedPayloadDigest returns the SHA3-256 digest of tx's serialized
+// reduced payload: the bytes Iroha signatories sign and torii hashes to
+// identify the transaction.
+func reducedPayloadDigest(tx *pb.Transaction) ([]byte, error) {
+	payload, err := proto.Marshal(tx.GetPayload().GetReducedPayload())
+	if err != nil {
+		return nil, err
+	}
+	sum := sha3.Sum256(payload)
+	return sum[:], nil
+}
@@ -0,0 +1,280 @@
+package iroha
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	pb "iroha_protocol"
+)
+
+// Page is the cursor-based result shape shared by the paginated query
+// wrappers below: a page of JSON-encoded items plus the cursor to pass as
+// the "first" argument of the next call, empty once there is nothing left
+// to fetch.
+type Page struct {
+	Items      []string
+	NextCursor string
+}
+
+// GetAccountDetailPaged wraps GetAccountDetail's underlying query with the
+// writer/key filter and AccountDetailPaginationMeta (page_size,
+// first_record_id) fields of the generated GetAccountDetail query, so
+// large detail sets can be walked a page at a time instead of returned as
+// one opaque blob.
+func GetAccountDetailPaged(account, writer, key, pageSize, firstRecordId string) (Page, error) {
+	size, err := strconv.Atoi(pageSize)
+	if err != nil {
+		return Page{}, err
+	}
+	if size <= 0 {
+		return Page{}, fmt.Errorf("GetAccountDetailPaged: pageSize must be positive, got %d", size)
+	}
+
+	cursorWriter, cursorKey, err := decodeAccountDetailCursor(firstRecordId)
+	if err != nil {
+		return Page{}, fmt.Errorf("GetAccountDetailPaged: invalid firstRecordId cursor: %w", err)
+	}
+
+	details, nextCursor, err := queryAccountDetailPage(account, writer, key, size, cursorWriter, cursorKey)
+	if err != nil {
+		return Page{}, err
+	}
+
+	return Page{Items: details, NextCursor: nextCursor}, nil
+}
+
+// GetAccountAssetsPaged wraps GetAccountAssets with the
+// AccountAssetPaginationMeta (page_size, first_asset_id) fields of the
+// generated GetAccountAssets query.
+func GetAccountAssetsPaged(account, pageSize, firstAssetId string) (Page, error) {
+	size, err := strconv.Atoi(pageSize)
+	if err != nil {
+		return Page{}, err
+	}
+	if size <= 0 {
+		return Page{}, fmt.Errorf("GetAccountAssetsPaged: pageSize must be positive, got %d", size)
+	}
+
+	assets, nextAssetId, err := queryAccountAssetsPage(account, size, firstAssetId)
+	if err != nil {
+		return Page{}, err
+	}
+
+	items := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		encoded, err := json.Marshal(asset)
+		if err != nil {
+			return Page{}, err
+		}
+		items = append(items, string(encoded))
+	}
+
+	return Page{Items: items, NextCursor: nextAssetId}, nil
+}
+
+// GetAccountTransactions wraps the generated GetAccountTransactions query
+// with its TxPaginationMeta (page_size, first_tx_hash) fields.
+func GetAccountTransactions(account, pageSize, firstTxHash string) (Page, error) {
+	size, err := strconv.Atoi(pageSize)
+	if err != nil {
+		return Page{}, err
+	}
+	if size <= 0 {
+		return Page{}, fmt.Errorf("GetAccountTransactions: pageSize must be positive, got %d", size)
+	}
+
+	txs, nextTxHash, err := queryAccountTransactionsPage(account, size, firstTxHash)
+	if err != nil {
+		return Page{}, err
+	}
+
+	items := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		encoded, err := json.Marshal(tx)
+		if err != nil {
+			return Page{}, err
+		}
+		items = append(items, string(encoded))
+	}
+
+	return Page{Items: items, NextCursor: nextTxHash}, nil
+}
+
+// GetBlockRange fetches at most pageSize sequential blocks starting at
+// fromHeight (capped at toHeight), reusing the existing single-height
+// GetBlock query since Iroha's block-store query is not itself paginated.
+// NextCursor is the height to resume from, empty once toHeight is reached.
+func GetBlockRange(fromHeight, toHeight, pageSize string) (Page, error) {
+	from, err := strconv.ParseInt(fromHeight, 10, 64)
+	if err != nil {
+		return Page{}, err
+	}
+	to, err := strconv.ParseInt(toHeight, 10, 64)
+	if err != nil {
+		return Page{}, err
+	}
+	size, err := strconv.Atoi(pageSize)
+	if err != nil {
+		return Page{}, err
+	}
+	if size <= 0 {
+		return Page{}, fmt.Errorf("GetBlockRange: pageSize must be positive, got %d", size)
+	}
+
+	var items []string
+	height := from
+	for ; height <= to && len(items) < size; height++ {
+		block, err := GetBlock(strconv.FormatInt(height, 10))
+		if err != nil {
+			return Page{}, err
+		}
+		encoded, err := json.Marshal(block)
+		if err != nil {
+			return Page{}, err
+		}
+		items = append(items, string(encoded))
+	}
+
+	nextCursor := ""
+	if height <= to {
+		nextCursor = strconv.FormatInt(height, 10)
+	}
+
+	return Page{Items: items, NextCursor: nextCursor}, nil
+}
+
+// accountDetailCursor is the opaque pagination cursor GetAccountDetailPaged
+// hands back as NextCursor and accepts back as firstRecordId. Iroha's own
+// AccountDetailRecordId needs both the writer and key half of a record to
+// resume correctly; encoding both here (instead of just the key, as the
+// writer *filter* argument was previously standing in for) keeps paging
+// correct when writer is left blank to page across every writer.
+type accountDetailCursor struct {
+	Writer string `json:"writer"`
+	Key    string `json:"key"`
+}
+
+func encodeAccountDetailCursor(writer, key string) string {
+	if writer == "" && key == "" {
+		return ""
+	}
+	encoded, _ := json.Marshal(accountDetailCursor{Writer: writer, Key: key})
+	return string(encoded)
+}
+
+func decodeAccountDetailCursor(cursor string) (writer, key string, err error) {
+	if cursor == "" {
+		return "", "", nil
+	}
+	var c accountDetailCursor
+	if err := json.Unmarshal([]byte(cursor), &c); err != nil {
+		return "", "", err
+	}
+	return c.Writer, c.Key, nil
+}
+
+// accountDetailRecord is one (writer, key, value) triple decoded out of
+// GetAccountDetailResponse's nested detail JSON (`{writer: {key: value}}`),
+// so queryAccountDetailPage can return one item per record instead of the
+// single opaque detail blob.
+type accountDetailRecord struct {
+	Writer string `json:"writer"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+// queryAccountDetailPage issues a GetAccountDetail query with its
+// AccountDetailPaginationMeta populated and returns the page's detail
+// records, one item per (writer, key) pair, plus the cursor to resume
+// from.
+func queryAccountDetailPage(account, writer, key string, pageSize int, cursorWriter, cursorKey string) ([]string, string, error) {
+	var firstRecordId *pb.AccountDetailRecordId
+	if cursorWriter != "" || cursorKey != "" {
+		firstRecordId = &pb.AccountDetailRecordId{Writer: cursorWriter, Key: cursorKey}
+	}
+
+	query := &pb.Query{Payload: &pb.Query_Payload{Query: &pb.Query_Payload_GetAccountDetail{
+		GetAccountDetail: &pb.GetAccountDetail{
+			AccountId: account,
+			Writer:    writer,
+			Key:       key,
+			PaginationMeta: &pb.AccountDetailPaginationMeta{
+				PageSize:      uint32(pageSize),
+				FirstRecordId: firstRecordId,
+			},
+		},
+	}}}
+
+	response, err := runQuery(query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page := response.GetAccountDetailResponse()
+
+	var byWriter map[string]map[string]string
+	if detail := page.GetDetail(); detail != "" {
+		if err := json.Unmarshal([]byte(detail), &byWriter); err != nil {
+			return nil, "", fmt.Errorf("queryAccountDetailPage: could not decode detail JSON: %w", err)
+		}
+	}
+
+	items := make([]string, 0, len(byWriter))
+	for recordWriter, kvs := range byWriter {
+		for recordKey, value := range kvs {
+			encoded, err := json.Marshal(accountDetailRecord{Writer: recordWriter, Key: recordKey, Value: value})
+			if err != nil {
+				return nil, "", err
+			}
+			items = append(items, string(encoded))
+		}
+	}
+
+	nextCursor := encodeAccountDetailCursor(page.GetNextRecordId().GetWriter(), page.GetNextRecordId().GetKey())
+	return items, nextCursor, nil
+}
+
+// queryAccountAssetsPage issues a GetAccountAssets query with its
+// AccountAssetPaginationMeta populated.
+func queryAccountAssetsPage(account string, pageSize int, firstAssetId string) ([]*pb.AccountAsset, string, error) {
+	query := &pb.Query{Payload: &pb.Query_Payload{Query: &pb.Query_Payload_GetAccountAssets{
+		GetAccountAssets: &pb.GetAccountAssets{
+			AccountId: account,
+			PaginationMeta: &pb.AccountAssetPaginationMeta{
+				PageSize:     uint32(pageSize),
+				FirstAssetId: firstAssetId,
+			},
+		},
+	}}}
+
+	response, err := runQuery(query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page := response.GetAccountAssetsResponse()
+	return page.GetAccountAssets(), page.GetNextAssetId(), nil
+}
+
+// queryAccountTransactionsPage issues a GetAccountTransactions query with
+// its TxPaginationMeta populated.
+func queryAccountTransactionsPage(account string, pageSize int, firstTxHash string) ([]*pb.Transaction, string, error) {
+	query := &pb.Query{Payload: &pb.Query_Payload{Query: &pb.Query_Payload_GetAccountTransactions{
+		GetAccountTransactions: &pb.GetAccountTransactions{
+			AccountId: account,
+			PaginationMeta: &pb.TxPaginationMeta{
+				PageSize:    uint32(pageSize),
+				FirstTxHash: firstTxHash,
+			},
+		},
+	}}}
+
+	response, err := runQuery(query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page := response.GetTransactionsPageResponse()
+	return page.GetTransactions(), page.GetNextTxHash(), nil
+}
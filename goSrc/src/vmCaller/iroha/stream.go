@@ -0,0 +1,60 @@
+package iroha
+
+import (
+	"context"
+
+	pb "iroha_protocol"
+)
+
+// BlockStream is a handle on a live subscription to Iroha's on-demand
+// block-store streaming service.
+type BlockStream struct {
+	Blocks <-chan *pb.Block
+	Errors <-chan error
+	Cancel func()
+}
+
+// CurrentHeight returns the height of the most recently committed block,
+// so a background watcher can start streaming from "now" instead of
+// replaying the whole chain from genesis on every process start.
+func CurrentHeight() (uint64, error) {
+	resp, err := blockStoreClient.GetTopBlockHeight(context.Background(), &pb.BlocksQuery{})
+	if err != nil {
+		return 0, err
+	}
+	return resp.GetHeight(), nil
+}
+
+// StreamBlocks opens a gRPC stream against Iroha's OnDemandOsProvider
+// starting at fromHeight, forwarding each committed block onto the
+// returned channel until Cancel is called or the stream errors.
+func StreamBlocks(fromHeight uint64) (*BlockStream, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := blockStoreClient.FetchBlocks(ctx, &pb.BlocksQuery{Height: fromHeight})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	blocks := make(chan *pb.Block)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(blocks)
+		for {
+			block, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case blocks <- block:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &BlockStream{Blocks: blocks, Errors: errs, Cancel: cancel}, nil
+}